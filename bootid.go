@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// getBootID 返回一个同一次开机期间保持不变、重启后一定变化的标识，
+// 用于在两次检查之间探测主机是否发生过重启（计数器会随之清零）
+func getBootID() (string, error) {
+	if data, err := ioutil.ReadFile("/proc/sys/kernel/random/boot_id"); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	}
+
+	// 非 Linux 平台或文件不可读时，退化为用开机时间戳代替
+	bootTime, err := host.BootTime()
+	if err != nil {
+		return "", fmt.Errorf("获取开机时间失败: %v", err)
+	}
+	return fmt.Sprintf("uptime-%d", bootTime), nil
+}
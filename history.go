@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	hourlyBucket = []byte("hourly")
+	dailyBucket  = []byte("daily")
+)
+
+const (
+	hourlyRetention = 24 * 14         // 保留最近 14 天的小时级样本
+	dailyRetention  = 400             // 保留最近约 13 个月的天级样本
+	historyKeyForm  = "2006-01-02T15" // 小时级样本的桶内主键格式
+	dailyKeyForm    = "2006-01-02"    // 天级样本的桶内主键格式
+)
+
+// HistorySample 是一个周期（小时或天）内的流量快照
+type HistorySample struct {
+	Timestamp time.Time `json:"timestamp"`
+	BytesIn   uint64    `json:"bytes_in"`
+	BytesOut  uint64    `json:"bytes_out"`
+}
+
+// HistoryStore 用 bbolt 持久化滚动的小时级/天级样本，重启后不丢失历史数据
+type HistoryStore struct {
+	db *bolt.DB
+}
+
+// OpenHistoryStore 打开（或创建）DataDir 下的历史数据库
+func OpenHistoryStore(dataDir string) (*HistoryStore, error) {
+	path := filepath.Join(dataDir, "history.db")
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("打开历史数据库失败: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(hourlyBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(dailyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化历史数据库失败: %v", err)
+	}
+
+	return &HistoryStore{db: db}, nil
+}
+
+// Close 关闭底层数据库
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// RecordHourly 写入一个小时级样本，并裁剪超出保留窗口的旧样本
+func (h *HistoryStore) RecordHourly(sample HistorySample) error {
+	return h.record(hourlyBucket, historyKeyForm, sample, hourlyRetention)
+}
+
+// RecordDaily 写入一个天级样本，并裁剪超出保留窗口的旧样本
+func (h *HistoryStore) RecordDaily(sample HistorySample) error {
+	return h.record(dailyBucket, dailyKeyForm, sample, dailyRetention)
+}
+
+func (h *HistoryStore) record(bucketName []byte, keyForm string, sample HistorySample, retention int) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("序列化历史样本失败: %v", err)
+	}
+
+	return h.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		key := []byte(sample.Timestamp.Format(keyForm))
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+		return pruneOldest(bucket, retention)
+	})
+}
+
+// pruneOldest 保持 bucket 中的样本数不超过 retention，按主键（时间）升序丢弃最旧的
+func pruneOldest(bucket *bolt.Bucket, retention int) error {
+	count := bucket.Stats().KeyN
+	if count <= retention {
+		return nil
+	}
+
+	cursor := bucket.Cursor()
+	toRemove := count - retention
+	for k, _ := cursor.First(); k != nil && toRemove > 0; k, _ = cursor.Next() {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+		toRemove--
+	}
+	return nil
+}
+
+// Hourly 返回最近 limit 个小时级样本，按时间升序排列
+func (h *HistoryStore) Hourly(limit int) ([]HistorySample, error) {
+	return h.recent(hourlyBucket, limit)
+}
+
+// Daily 返回最近 limit 个天级样本，按时间升序排列
+func (h *HistoryStore) Daily(limit int) ([]HistorySample, error) {
+	return h.recent(dailyBucket, limit)
+}
+
+func (h *HistoryStore) recent(bucketName []byte, limit int) ([]HistorySample, error) {
+	var all []HistorySample
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		return bucket.ForEach(func(k, v []byte) error {
+			var sample HistorySample
+			if err := json.Unmarshal(v, &sample); err != nil {
+				return err
+			}
+			all = append(all, sample)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return all, nil
+}
+
+// historyResponse 是 /history 接口返回的 JSON 结构
+type historyResponse struct {
+	Hourly []HistorySample `json:"hourly"`
+	Daily  []HistorySample `json:"daily"`
+}
+
+// handleHistory 提供 JSON 格式的历史流量数据，供 Grafana 等工具直接拉取
+func (app *App) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if app.History == nil {
+		http.Error(w, "历史数据未启用", http.StatusServiceUnavailable)
+		return
+	}
+
+	hourly, err := app.History.Hourly(hourlyRetention)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("读取小时级历史失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	daily, err := app.History.Daily(dailyRetention)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("读取天级历史失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(historyResponse{Hourly: hourly, Daily: daily})
+}
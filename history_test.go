@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestPruneOldest(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "history.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	bucketName := []byte("test")
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+		for _, key := range []string{"1", "2", "3", "4", "5"} {
+			if err := bucket.Put([]byte(key), []byte("v")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	// bucket.Stats() 只反映已落盘的页，需在独立事务中裁剪才能看到准确的计数
+	err = db.Update(func(tx *bolt.Tx) error {
+		return pruneOldest(tx.Bucket(bucketName), 3)
+	})
+	if err != nil {
+		t.Fatalf("pruneOldest 失败: %v", err)
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if count := bucket.Stats().KeyN; count != 3 {
+			t.Errorf("裁剪后剩余 %d 条记录，期望 3 条", count)
+		}
+		for _, key := range []string{"1", "2"} {
+			if bucket.Get([]byte(key)) != nil {
+				t.Errorf("最旧的记录 %q 应该已被丢弃", key)
+			}
+		}
+		for _, key := range []string{"3", "4", "5"} {
+			if bucket.Get([]byte(key)) == nil {
+				t.Errorf("最新的记录 %q 不应该被丢弃", key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("读取测试数据库失败: %v", err)
+	}
+}
+
+func TestPruneOldestWithinRetention(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "history.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	bucketName := []byte("test")
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("1"), []byte("v"))
+	})
+	if err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		return pruneOldest(tx.Bucket(bucketName), 3)
+	})
+	if err != nil {
+		t.Fatalf("pruneOldest 失败: %v", err)
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		if count := tx.Bucket(bucketName).Stats().KeyN; count != 1 {
+			t.Errorf("记录数未超过 retention 时不应该裁剪，剩余 %d 条", count)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("读取测试数据库失败: %v", err)
+	}
+}
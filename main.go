@@ -5,54 +5,84 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/shirou/gopsutil/v3/net"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // 配置结构体
 type Config struct {
 	// 基本配置
-	MonthlyResetDay      int      `json:"monthly_reset_day"`      // 每月更新日期
-	NetworkInterface     string   `json:"network_interface"`      // 要监控的网络接口
-	TrafficMode          string   `json:"traffic_mode"`           // 流量模式：in, out, max, both
-	WarningThresholdGB   float64  `json:"warning_threshold_gb"`   // 警告阈值（GB）
-	CheckIntervalSeconds int      `json:"check_interval_seconds"` // 检查间隔（秒）
-	DataDir              string   `json:"data_dir"`               // 数据存储目录
+	MonthlyResetDay      int               `json:"monthly_reset_day"`      // 每月更新日期
+	Interfaces           []InterfaceConfig `json:"interfaces"`             // 要监控的网络接口
+	AggregateRule        string            `json:"aggregate_rule"`         // 跨网卡聚合规则："", "sum", "max"
+	AggregateThresholdGB float64           `json:"aggregate_threshold_gb"` // 聚合流量告警阈值（GB），0 表示不启用
+	CheckIntervalSeconds int               `json:"check_interval_seconds"` // 检查间隔（秒）
+	DataDir              string            `json:"data_dir"`               // 数据存储目录
+	MetricsListen        string            `json:"metrics_listen"`         // Prometheus 指标监听地址，如 ":9273"，留空则不启动
 
 	// Telegram机器人配置
-	TelegramBotToken string   `json:"telegram_bot_token"` // Telegram Bot Token
-	TelegramChatIDs  []int64  `json:"telegram_chat_ids"`  // Telegram聊天ID
-	ServerName string `json:"server_name"` // 服务器名称
-	ShutdownOnWarning    bool     `json:"shutdown_on_warning"` // 达到月流量阈值时是否关机
+	TelegramBotToken  string         `json:"telegram_bot_token"`  // Telegram Bot Token
+	TelegramChatIDs   []int64        `json:"telegram_chat_ids"`   // Telegram聊天ID
+	ServerName        string         `json:"server_name"`         // 服务器名称
+	ShutdownOnWarning bool           `json:"shutdown_on_warning"` // 达到月流量阈值时是否关机
+	ShutdownPolicy    ShutdownPolicy `json:"shutdown_policy"`     // 关机执行策略（宽限期、是否需要确认、dry_run 等）
+
+	// 通知渠道配置，留空时退化为直接使用 Telegram Bot
+	Notifiers []NotifierConfig `json:"notifiers"`
 }
 
-// 流量统计结构体
-type TrafficStats struct {
-	CurrentMonth          string    `json:"current_month"`
-	LastResetTime         time.Time `json:"last_reset_time"`
-	NextResetTime         time.Time `json:"next_reset_time"`
-	BytesIn               uint64    `json:"bytes_in"`
-	BytesOut              uint64    `json:"bytes_out"`
-	LastBytesIn           uint64    `json:"last_bytes_in"`
-	LastBytesOut          uint64    `json:"last_bytes_out"`
-	WarningsSentThisMonth bool      `json:"warnings_sent_this_month"`
+// NotifierConfig 描述一个通知渠道；不同类型只使用其中相关的字段
+type NotifierConfig struct {
+	Type string `json:"type"` // telegram, webhook, discord, smtp, serverchan
+
+	URL     string `json:"url,omitempty"`      // webhook 地址
+	Webhook string `json:"webhook,omitempty"`  // discord incoming webhook 地址
+	SendKey string `json:"send_key,omitempty"` // server酱 SendKey
+
+	SMTPHost     string   `json:"smtp_host,omitempty"`
+	SMTPPort     int      `json:"smtp_port,omitempty"`
+	SMTPUsername string   `json:"smtp_username,omitempty"`
+	SMTPPassword string   `json:"smtp_password,omitempty"`
+	From         string   `json:"from,omitempty"`
+	To           []string `json:"to,omitempty"`
 }
 
 // 应用结构体
 type App struct {
-	Config      Config
-	Stats       TrafficStats
-	Bot         *tgbotapi.BotAPI
-	mu          sync.Mutex
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
+	Config    Config
+	Stats     TrafficStats
+	Bot       *tgbotapi.BotAPI
+	Metrics   *Metrics
+	History   *HistoryStore
+	Notifiers *NotifierManager
+	Muted     bool
+	mu        sync.Mutex
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+
+	metricsServer      *http.Server
+	commands           map[string]CommandHandler
+	shutdownCancelChan chan struct{}
+	pendingShutdown    *pendingShutdown
+
+	configPath       string
+	reloadTickerChan chan int
+
+	// 滚动小时级/天级样本的累加状态
+	hourBucket   string
+	hourAccumIn  uint64
+	hourAccumOut uint64
+	dayBucket    string
+	dayAccumIn   uint64
+	dayAccumOut  uint64
 }
 
 // 初始化应用
@@ -62,6 +92,9 @@ func NewApp(configPath string) (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("无法加载配置: %v", err)
 	}
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("配置校验失败: %v", err)
+	}
 
 	// 创建数据目录
 	if config.DataDir == "" {
@@ -84,10 +117,30 @@ func NewApp(configPath string) (*App, error) {
 
 	// 初始化应用
 	app := &App{
-		Config:   config,
-		Bot:      bot,
-		stopChan: make(chan struct{}),
+		Config:           config,
+		Bot:              bot,
+		Metrics:          NewMetrics(),
+		stopChan:         make(chan struct{}),
+		commands:         make(map[string]CommandHandler),
+		configPath:       configPath,
+		reloadTickerChan: make(chan int, 1),
+	}
+	app.registerBuiltinCommands()
+
+	// 构建通知渠道并启动后台分发协程
+	notifiers, err := buildNotifiers(config, bot)
+	if err != nil {
+		return nil, fmt.Errorf("初始化通知渠道失败: %v", err)
+	}
+	app.Notifiers = NewNotifierManager(notifiers, app.Metrics)
+	app.Notifiers.Start()
+
+	// 打开历史数据库，用于持久化小时级/天级样本
+	history, err := OpenHistoryStore(config.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("打开历史数据库失败: %v", err)
 	}
+	app.History = history
 
 	// 加载或初始化统计数据
 	err = app.loadOrInitStats()
@@ -98,6 +151,39 @@ func NewApp(configPath string) (*App, error) {
 	return app, nil
 }
 
+// legacyConfig 是重构前的单网卡配置格式，仅用于首次启动时的迁移
+type legacyConfig struct {
+	NetworkInterface   string  `json:"network_interface"`
+	TrafficMode        string  `json:"traffic_mode"`
+	WarningThresholdGB float64 `json:"warning_threshold_gb"`
+}
+
+// isLegacyConfigFormat 判断磁盘上的配置文件是否是重构前的单网卡格式
+func isLegacyConfigFormat(data []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	_, hasInterfaces := probe["interfaces"]
+	_, hasLegacyInterface := probe["network_interface"]
+	return !hasInterfaces && hasLegacyInterface
+}
+
+// migrateLegacyConfig 把单网卡的 config.json 迁移到 Interfaces 列表，
+// 避免用户升级后原有网卡和阈值配置悄悄消失
+func migrateLegacyConfig(config *Config, data []byte) error {
+	var legacy legacyConfig
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("解析旧版配置失败: %v", err)
+	}
+
+	config.Interfaces = []InterfaceConfig{
+		{Name: legacy.NetworkInterface, TrafficMode: legacy.TrafficMode, ThresholdGB: legacy.WarningThresholdGB},
+	}
+	log.Printf("已将旧版单网卡配置迁移到 interfaces 列表: %s", legacy.NetworkInterface)
+	return nil
+}
+
 // 加载配置
 func loadConfig(configPath string) (Config, error) {
 	var config Config
@@ -107,15 +193,19 @@ func loadConfig(configPath string) (Config, error) {
 		if os.IsNotExist(err) {
 			// 如果配置文件不存在，创建默认配置
 			config = Config{
-				MonthlyResetDay:      1,
-				NetworkInterface:     "eth0",
-				TrafficMode:          "both",
-				WarningThresholdGB:   1000, // 1000GB = 1TB
-				CheckIntervalSeconds: 300,  // 5分钟
+				MonthlyResetDay: 1,
+				Interfaces: []InterfaceConfig{
+					{Name: "eth0", TrafficMode: "both", ThresholdGB: 1000}, // 1000GB = 1TB
+				},
+				CheckIntervalSeconds: 300, // 5分钟
 				DataDir:              "./data",
 				TelegramBotToken:     "",
 				TelegramChatIDs:      []int64{},
 				ServerName:           "MyServer",
+				ShutdownPolicy: ShutdownPolicy{
+					GracePeriodSeconds: 10,
+					Method:             "poweroff",
+				},
 			}
 
 			// 保存默认配置
@@ -140,6 +230,13 @@ func loadConfig(configPath string) (Config, error) {
 		return config, fmt.Errorf("解析配置文件失败: %v", err)
 	}
 
+	// 旧版是单网卡的扁平结构，先迁移再继续，避免用户升级后网卡配置丢失
+	if isLegacyConfigFormat(data) {
+		if err := migrateLegacyConfig(&config, data); err != nil {
+			return config, err
+		}
+	}
+
 	return config, nil
 }
 
@@ -150,28 +247,40 @@ func (app *App) loadOrInitStats() error {
 
 	statsPath := filepath.Join(app.Config.DataDir, "traffic_stats.json")
 	data, err := ioutil.ReadFile(statsPath)
-	
+
 	if err != nil {
 		if os.IsNotExist(err) {
-			// 如果统计数据不存在，初始化
+			// 如果统计数据不存在，为每个配置的网卡初始化基准
 			now := time.Now()
-			nextResetTime := getNextResetTime(now, app.Config.MonthlyResetDay)
-			
-			// 获取当前流量基准
-			bytesIn, bytesOut, err := getCurrentTrafficBytes(app.Config.NetworkInterface)
+
+			counters, err := getCurrentTrafficBytes()
 			if err != nil {
 				return fmt.Errorf("获取当前流量失败: %v", err)
 			}
 
+			interfaces := make(map[string]*InterfaceStats, len(app.Config.Interfaces))
+			for _, cfg := range app.Config.Interfaces {
+				stats := &InterfaceStats{}
+				if counter, ok := findCounter(counters, cfg.Name); ok {
+					stats.LastBytesIn = counter.BytesRecv
+					stats.LastBytesOut = counter.BytesSent
+				} else {
+					log.Printf("初始化统计时找不到网络接口: %s", cfg.Name)
+				}
+				interfaces[cfg.Name] = stats
+			}
+
+			bootID, err := getBootID()
+			if err != nil {
+				log.Printf("获取开机标识失败，跳过重启检测: %v", err)
+			}
+
 			app.Stats = TrafficStats{
-				CurrentMonth:          now.Format("2006-01"),
-				LastResetTime:         now,
-				NextResetTime:         nextResetTime,
-				BytesIn:               0,
-				BytesOut:              0,
-				LastBytesIn:           bytesIn,
-				LastBytesOut:          bytesOut,
-				WarningsSentThisMonth: false,
+				CurrentMonth:  now.Format("2006-01"),
+				LastResetTime: now,
+				NextResetTime: getNextResetTime(now, app.Config.MonthlyResetDay),
+				Interfaces:    interfaces,
+				BootID:        bootID,
 			}
 
 			// 保存初始化的统计数据
@@ -180,32 +289,55 @@ func (app *App) loadOrInitStats() error {
 		return err
 	}
 
+	// 旧版是单网卡的扁平结构，先迁移再继续
+	if isLegacyStatsFormat(data) {
+		return app.migrateLegacyStats(data)
+	}
+
 	// 解析统计数据
 	err = json.Unmarshal(data, &app.Stats)
 	if err != nil {
 		return fmt.Errorf("解析统计数据失败: %v", err)
 	}
+	if app.Stats.Interfaces == nil {
+		app.Stats.Interfaces = make(map[string]*InterfaceStats)
+	}
+
+	// 通过 boot_id 探测两次检查之间主机是否重启过；重启会让内核计数器清零，
+	// 单靠"本次读数 < 上次读数"不足以覆盖重启后计数器碰巧仍然偏大的情况
+	if bootID, err := getBootID(); err != nil {
+		log.Printf("获取开机标识失败，跳过重启检测: %v", err)
+	} else {
+		if app.Stats.BootID != "" && app.Stats.BootID != bootID {
+			log.Println("检测到主机已重启，重置所有网卡的计数器基准")
+			for _, stats := range app.Stats.Interfaces {
+				stats.LastBytesIn = 0
+				stats.LastBytesOut = 0
+				stats.CounterResets++
+			}
+		}
+		app.Stats.BootID = bootID
+	}
 
 	// 检查是否需要重置统计
 	now := time.Now()
 	if now.After(app.Stats.NextResetTime) {
 		// 发送月度报告
 		app.sendMonthlyReport()
-		
-		// 重置统计
-		bytesIn, bytesOut, err := getCurrentTrafficBytes(app.Config.NetworkInterface)
+
+		// 重置统计，并刷新每个网卡的计数器基准
+		counters, err := getCurrentTrafficBytes()
 		if err != nil {
 			return fmt.Errorf("获取当前流量失败: %v", err)
 		}
 
-		app.Stats.CurrentMonth = now.Format("2006-01")
-		app.Stats.LastResetTime = now
-		app.Stats.NextResetTime = getNextResetTime(now, app.Config.MonthlyResetDay)
-		app.Stats.BytesIn = 0
-		app.Stats.BytesOut = 0
-		app.Stats.LastBytesIn = bytesIn
-		app.Stats.LastBytesOut = bytesOut
-		app.Stats.WarningsSentThisMonth = false
+		app.resetMonthlyStats()
+		for name, stats := range app.Stats.Interfaces {
+			if counter, ok := findCounter(counters, name); ok {
+				stats.LastBytesIn = counter.BytesRecv
+				stats.LastBytesOut = counter.BytesSent
+			}
+		}
 
 		return app.saveStats()
 	}
@@ -216,31 +348,31 @@ func (app *App) loadOrInitStats() error {
 // 保存统计数据
 func (app *App) saveStats() error {
 	statsPath := filepath.Join(app.Config.DataDir, "traffic_stats.json")
-	
+
 	data, err := json.MarshalIndent(app.Stats, "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化统计数据失败: %v", err)
 	}
-	
+
 	err = ioutil.WriteFile(statsPath, data, 0644)
 	if err != nil {
 		return fmt.Errorf("保存统计数据失败: %v", err)
 	}
-	
+
 	return nil
 }
 
 // 获取下一个重置时间
 func getNextResetTime(now time.Time, resetDay int) time.Time {
 	year, month, _ := now.Date()
-	
+
 	// 确保重置日在1-28之间（避免月末问题）
 	if resetDay < 1 {
 		resetDay = 1
 	} else if resetDay > 28 {
 		resetDay = 28
 	}
-	
+
 	// 计算下一个重置时间
 	nextMonth := month + 1
 	nextYear := year
@@ -248,56 +380,61 @@ func getNextResetTime(now time.Time, resetDay int) time.Time {
 		nextMonth = 1
 		nextYear++
 	}
-	
-	return time.Date(nextYear, nextMonth, resetDay, 0, 0, 0, 0, now.Location())
-}
 
-// 获取当前网络接口的流量字节数
-func getCurrentTrafficBytes(interfaceName string) (uint64, uint64, error) {
-	stats, err := net.IOCounters(true)
-	if err != nil {
-		return 0, 0, err
-	}
-	
-	for _, stat := range stats {
-		if stat.Name == interfaceName {
-			return stat.BytesRecv, stat.BytesSent, nil
-		}
-	}
-	
-	return 0, 0, fmt.Errorf("找不到网络接口: %s", interfaceName)
+	return time.Date(nextYear, nextMonth, resetDay, 0, 0, 0, 0, now.Location())
 }
 
 // 启动应用
 func (app *App) Start() {
 	log.Println("开始监控网络流量...")
-	
+
+	app.startMetricsServer()
+	app.startTelegramListener()
+	app.startConfigWatcher()
+
 	app.wg.Add(1)
 	go app.monitorTraffic()
-	
+
 	// 设置信号处理以便优雅退出
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	
+
 	<-sigChan
 	log.Println("正在关闭应用...")
-	
+
 	close(app.stopChan)
 	app.wg.Wait()
-	
+
 	// 保存最终统计结果
 	app.updateTrafficStats()
-	
+
+	// 把尚未跨小时/天的累加器落盘，避免重启丢失最长 59 分钟/23 小时的已采集历史
+	app.flushPendingHistory()
+
+	if app.Notifiers != nil {
+		app.Notifiers.Stop()
+	}
+
+	if app.History != nil {
+		if err := app.History.Close(); err != nil {
+			log.Printf("关闭历史数据库失败: %v", err)
+		}
+	}
+
 	log.Println("应用已关闭")
 }
 
 // 监控流量
 func (app *App) monitorTraffic() {
 	defer app.wg.Done()
-	
-	ticker := time.NewTicker(time.Duration(app.Config.CheckIntervalSeconds) * time.Second)
+
+	app.mu.Lock()
+	interval := app.Config.CheckIntervalSeconds
+	app.mu.Unlock()
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
 	defer ticker.Stop()
-	
+
 	for {
 		select {
 		case <-ticker.C:
@@ -305,6 +442,10 @@ func (app *App) monitorTraffic() {
 			if err != nil {
 				log.Printf("更新流量统计失败: %v", err)
 			}
+		case newInterval := <-app.reloadTickerChan:
+			ticker.Stop()
+			ticker = time.NewTicker(time.Duration(newInterval) * time.Second)
+			log.Printf("检查间隔已更新为 %d 秒，定时器已重建", newInterval)
 		case <-app.stopChan:
 			return
 		}
@@ -315,162 +456,204 @@ func (app *App) monitorTraffic() {
 func (app *App) updateTrafficStats() error {
 	app.mu.Lock()
 	defer app.mu.Unlock()
-	
-	// 获取当前流量
-	currentBytesIn, currentBytesOut, err := getCurrentTrafficBytes(app.Config.NetworkInterface)
+
+	// 一次性获取所有网卡的计数器
+	counters, err := getCurrentTrafficBytes()
 	if err != nil {
 		return err
 	}
-	
-	// 计算增量
-	inDelta := currentBytesIn - app.Stats.LastBytesIn
-	outDelta := currentBytesOut - app.Stats.LastBytesOut
-	
-	// 更新累计统计
-	app.Stats.BytesIn += inDelta
-	app.Stats.BytesOut += outDelta
-	app.Stats.LastBytesIn = currentBytesIn
-	app.Stats.LastBytesOut = currentBytesOut
-	
-	// 检查是否需要发送警告
-	if !app.Stats.WarningsSentThisMonth {
-		var totalTraffic uint64
-		
-		switch app.Config.TrafficMode {
-		case "in":
-			totalTraffic = app.Stats.BytesIn
-		case "out":
-			totalTraffic = app.Stats.BytesOut
-		case "max":
-			if app.Stats.BytesIn > app.Stats.BytesOut {
-				totalTraffic = app.Stats.BytesIn
-			} else {
-				totalTraffic = app.Stats.BytesOut
+
+	var aggInDelta, aggOutDelta uint64
+
+	for _, cfg := range app.Config.Interfaces {
+		counter, ok := findCounter(counters, cfg.Name)
+		if !ok {
+			log.Printf("找不到网络接口: %s", cfg.Name)
+			continue
+		}
+
+		stats := app.Stats.Interfaces[cfg.Name]
+		if stats == nil {
+			stats = &InterfaceStats{LastBytesIn: counter.BytesRecv, LastBytesOut: counter.BytesSent}
+			app.Stats.Interfaces[cfg.Name] = stats
+		}
+
+		inDelta, inReset := computeDelta(counter.BytesRecv, stats.LastBytesIn)
+		outDelta, outReset := computeDelta(counter.BytesSent, stats.LastBytesOut)
+		if inReset || outReset {
+			stats.CounterResets++
+			log.Printf("检测到网卡 %s 计数器重置，按新基准计数 (入站: last=%d current=%d, 出站: last=%d current=%d)",
+				cfg.Name, stats.LastBytesIn, counter.BytesRecv, stats.LastBytesOut, counter.BytesSent)
+		}
+
+		stats.BytesIn += inDelta
+		stats.BytesOut += outDelta
+		stats.LastBytesIn = counter.BytesRecv
+		stats.LastBytesOut = counter.BytesSent
+
+		app.Metrics.update(cfg.Name, inDelta, outDelta, stats.BytesIn, stats.BytesOut, stats.WarningsSentThisMonth)
+		app.Metrics.updateCounterResets(cfg.Name, stats.CounterResets)
+		aggInDelta += inDelta
+		aggOutDelta += outDelta
+
+		// 检查该网卡是否需要单独发送警告
+		if cfg.ThresholdGB > 0 && !stats.WarningsSentThisMonth {
+			total := trafficTotal(cfg.TrafficMode, stats.BytesIn, stats.BytesOut)
+			if total >= gbToBytes(cfg.ThresholdGB) {
+				app.sendWarningMessage(cfg.DisplayLabel(), total, cfg.ThresholdGB)
+				stats.WarningsSentThisMonth = true
 			}
-		case "both":
-			totalTraffic = app.Stats.BytesIn + app.Stats.BytesOut
 		}
-		
-		// 将GB转换为字节进行比较
-		warningThresholdBytes := uint64(app.Config.WarningThresholdGB * 1024 * 1024 * 1024)
-		if totalTraffic >= warningThresholdBytes {
-			app.sendWarningMessage(totalTraffic)
-			app.Stats.WarningsSentThisMonth = true
+	}
+
+	app.recordHistorySample(aggInDelta, aggOutDelta)
+
+	// 检查跨网卡聚合规则是否触发
+	if app.Config.AggregateRule != "" && app.Config.AggregateThresholdGB > 0 {
+		if !app.Stats.AggregateWarningSent {
+			aggTotal := app.aggregateTraffic()
+			if aggTotal >= gbToBytes(app.Config.AggregateThresholdGB) {
+				app.sendWarningMessage(fmt.Sprintf("聚合(%s)", app.Config.AggregateRule), aggTotal, app.Config.AggregateThresholdGB)
+				app.Stats.AggregateWarningSent = true
+			}
 		}
+		app.Metrics.updateAggregate(app.Stats.AggregateWarningSent)
 	}
-	
+
 	// 检查是否需要重置（以防定时器错过）
 	now := time.Now()
 	if now.After(app.Stats.NextResetTime) {
 		// 发送月度报告
 		app.sendMonthlyReport()
-		
+
 		// 重置统计
-		app.Stats.CurrentMonth = now.Format("2006-01")
-		app.Stats.LastResetTime = now
-		app.Stats.NextResetTime = getNextResetTime(now, app.Config.MonthlyResetDay)
-		app.Stats.BytesIn = 0
-		app.Stats.BytesOut = 0
-		app.Stats.WarningsSentThisMonth = false
-	}
-	
+		app.resetMonthlyStats()
+	}
+
 	// 保存统计
 	return app.saveStats()
 }
 
-// 发送警告消息
-func (app *App) sendWarningMessage(totalBytes uint64) {
-	if app.Bot == nil {
-		log.Println("警告：流量超过阈值，但未配置Telegram Bot")
+// recordHistorySample 将本次 tick 的增量累加进当前小时/天的样本，
+// 跨入下一个小时/天时把累加好的样本落盘，供 /history 接口和 Grafana 读取
+func (app *App) recordHistorySample(inDelta, outDelta uint64) {
+	if app.History == nil {
 		return
 	}
-	
-	// 计算GB
-	totalGB := float64(totalBytes) / 1024 / 1024 / 1024
-	
-        message := fmt.Sprintf("🚨 [%s] 流量警告: 本月已使用 %.2f GB，超过警告阈值 %.2f GB",
-        		app.Config.ServerName, totalGB, app.Config.WarningThresholdGB)
-
-	for _, chatID := range app.Config.TelegramChatIDs {
-		msg := tgbotapi.NewMessage(chatID, message)
-		_, err := app.Bot.Send(msg)
-		if err != nil {
-			log.Printf("发送警告消息失败 (chatID: %d): %v", chatID, err)
+
+	now := time.Now()
+
+	hourKey := now.Format(historyKeyForm)
+	if app.hourBucket == "" {
+		app.hourBucket = hourKey
+	} else if hourKey != app.hourBucket {
+		app.flushHistoryBucket(now, true)
+	}
+	app.hourAccumIn += inDelta
+	app.hourAccumOut += outDelta
+	app.hourBucket = hourKey
+
+	dayKey := now.Format(dailyKeyForm)
+	if app.dayBucket == "" {
+		app.dayBucket = dayKey
+	} else if dayKey != app.dayBucket {
+		app.flushHistoryBucket(now, false)
+	}
+	app.dayAccumIn += inDelta
+	app.dayAccumOut += outDelta
+	app.dayBucket = dayKey
+}
+
+// flushHistoryBucket 把已经累加完的小时级或天级样本写入历史数据库并清零累加器
+func (app *App) flushHistoryBucket(now time.Time, hourly bool) {
+	if hourly {
+		sample := HistorySample{Timestamp: now, BytesIn: app.hourAccumIn, BytesOut: app.hourAccumOut}
+		if err := app.History.RecordHourly(sample); err != nil {
+			log.Printf("写入小时级历史样本失败: %v", err)
 		}
+		app.hourAccumIn, app.hourAccumOut = 0, 0
+		return
 	}
-	
-	log.Println("已发送流量警告消息")
+
+	sample := HistorySample{Timestamp: now, BytesIn: app.dayAccumIn, BytesOut: app.dayAccumOut}
+	if err := app.History.RecordDaily(sample); err != nil {
+		log.Printf("写入天级历史样本失败: %v", err)
+	}
+	app.dayAccumIn, app.dayAccumOut = 0, 0
+}
+
+// flushPendingHistory 在应用退出前把尚未跨桶、还只停留在内存累加器里的小时/天样本落盘，
+// 否则这部分已经采集到的历史数据会随进程退出丢失
+func (app *App) flushPendingHistory() {
+	if app.History == nil {
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	now := time.Now()
+	if app.hourBucket != "" {
+		app.flushHistoryBucket(now, true)
+	}
+	if app.dayBucket != "" {
+		app.flushHistoryBucket(now, false)
+	}
+}
+
+// notify 把一条通知事件交给通知渠道分发器，不配置任何渠道时只记录日志；
+// 调用方需持有 app.mu，因为 app.Notifiers 在热重载时会被并发替换
+func (app *App) notify(title, message string) {
+	if app.Notifiers == nil {
+		log.Printf("未配置任何通知渠道，跳过发送: %s", title)
+		return
+	}
+	app.Notifiers.Notify(Event{Title: title, Message: message, Time: time.Now()})
+}
+
+// 发送警告消息
+func (app *App) sendWarningMessage(label string, totalBytes uint64, thresholdGB float64) {
+	if app.Muted {
+		log.Println("已静音，跳过本次流量警告")
+		return
+	}
+
+	// 计算GB
+	totalGB := bytesToGB(totalBytes)
+
+	message := fmt.Sprintf("🚨 [%s] 流量警告 (%s): 本月已使用 %.2f GB，超过警告阈值 %.2f GB",
+		app.Config.ServerName, label, totalGB, thresholdGB)
+
+	app.notify("流量警告", message)
 
 	if app.Config.ShutdownOnWarning {
-                log.Println("达到阈值，准备关机...")
-                
-		for _, chatID := range app.Config.TelegramChatIDs {
-     		   msg := tgbotapi.NewMessage(chatID, message)
-      		   _, err := app.Bot.Send(msg)
-           	   if err != nil {
-           	   log.Printf("发送关机警告消息失败 (chatID: %d): %v", chatID, err)
-       		   }
-    	        }
-                
-                go func() {
-                        time.Sleep(10 * time.Second) // 留时间发送完消息
-                        syscall.Sync()
-                        syscall.Reboot(syscall.LINUX_REBOOT_CMD_POWER_OFF)
-                }()
-        }
+		app.scheduleShutdown()
+	}
 }
 
 // 发送月度报告
 func (app *App) sendMonthlyReport() {
-	if app.Bot == nil {
-		log.Println("月度报告：未配置Telegram Bot，跳过发送")
-		return
-	}
-	
-	var totalTraffic uint64
-	
-	switch app.Config.TrafficMode {
-	case "in":
-		totalTraffic = app.Stats.BytesIn
-	case "out":
-		totalTraffic = app.Stats.BytesOut
-	case "max":
-		if app.Stats.BytesIn > app.Stats.BytesOut {
-			totalTraffic = app.Stats.BytesIn
-		} else {
-			totalTraffic = app.Stats.BytesOut
-		}
-	case "both":
-		totalTraffic = app.Stats.BytesIn + app.Stats.BytesOut
-	}
-	
-	inGB := float64(app.Stats.BytesIn) / 1024 / 1024 / 1024
-	outGB := float64(app.Stats.BytesOut) / 1024 / 1024 / 1024
-	totalGB := float64(totalTraffic) / 1024 / 1024 / 1024
-	
-	message := fmt.Sprintf("📊 [%s] 月度流量报告 (%s)\n\n"+
-        "- 入站流量: %.2f GB\n"+
-        "- 出站流量: %.2f GB\n"+
-        "- 总计流量: %.2f GB\n\n"+
-        "流量统计模式: %s\n"+
-        "下次重置时间: %s",
-        app.Config.ServerName,
-        app.Stats.CurrentMonth,
-        inGB,
-        outGB,
-        totalGB,
-        getTrafficModeDescription(app.Config.TrafficMode),
-        app.Stats.NextResetTime.Format("2006-01-02"))
-
-
-	for _, chatID := range app.Config.TelegramChatIDs {
-		msg := tgbotapi.NewMessage(chatID, message)
-		_, err := app.Bot.Send(msg)
-		if err != nil {
-			log.Printf("发送月度报告失败 (chatID: %d): %v", chatID, err)
+	var body strings.Builder
+	fmt.Fprintf(&body, "📊 [%s] 月度流量报告 (%s)", app.Config.ServerName, app.Stats.CurrentMonth)
+
+	for _, cfg := range app.Config.Interfaces {
+		stats := app.Stats.Interfaces[cfg.Name]
+		if stats == nil {
+			continue
 		}
+		total := trafficTotal(cfg.TrafficMode, stats.BytesIn, stats.BytesOut)
+		fmt.Fprintf(&body, "\n\n%s (%s):\n- 入站流量: %.2f GB\n- 出站流量: %.2f GB\n- 总计流量: %.2f GB",
+			cfg.DisplayLabel(), getTrafficModeDescription(cfg.TrafficMode),
+			bytesToGB(stats.BytesIn), bytesToGB(stats.BytesOut), bytesToGB(total))
 	}
-	
+
+	if app.Config.AggregateRule != "" {
+		fmt.Fprintf(&body, "\n\n聚合流量 (%s): %.2f GB", app.Config.AggregateRule, bytesToGB(app.aggregateTraffic()))
+	}
+
+	fmt.Fprintf(&body, "\n\n下次重置时间: %s", app.Stats.NextResetTime.Format("2006-01-02"))
+
+	app.notify("月度流量报告", body.String())
 	log.Println("已发送月度流量报告")
 }
 
@@ -493,18 +676,18 @@ func getTrafficModeDescription(mode string) string {
 // 主函数
 func main() {
 	log.Println("服务器月流量统计启动中...")
-	
+
 	configPath := "config.json"
-	
+
 	// 检查命令行参数
 	if len(os.Args) > 1 {
 		configPath = os.Args[1]
 	}
-	
+
 	app, err := NewApp(configPath)
 	if err != nil {
 		log.Fatalf("初始化应用失败: %v", err)
 	}
-	
+
 	app.Start()
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics 持有对外暴露的 Prometheus 指标
+type Metrics struct {
+	registry               *prometheus.Registry
+	bytesInDelta           *prometheus.GaugeVec
+	bytesOutDelta          *prometheus.GaugeVec
+	bytesInTotal           *prometheus.GaugeVec
+	bytesOutTotal          *prometheus.GaugeVec
+	warningActive          *prometheus.GaugeVec
+	aggregateWarningActive prometheus.Gauge
+	counterResets          *prometheus.GaugeVec
+	notifierFailures       *prometheus.CounterVec
+}
+
+// NewMetrics 创建指标集合并注册到一个独立的 registry
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+		bytesInDelta: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "bytes_in_delta",
+			Help:      "本次采样周期内接收到的字节数（按网卡）",
+		}, []string{"interface"}),
+		bytesOutDelta: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "bytes_out_delta",
+			Help:      "本次采样周期内发送的字节数（按网卡）",
+		}, []string{"interface"}),
+		bytesInTotal: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "bytes_in_total",
+			Help:      "当月累计接收字节数（按网卡）",
+		}, []string{"interface"}),
+		bytesOutTotal: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "bytes_out_total",
+			Help:      "当月累计发送字节数（按网卡）",
+		}, []string{"interface"}),
+		warningActive: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "warning_active",
+			Help:      "本月流量警告是否已触发（1 表示已触发，按网卡）",
+		}, []string{"interface"}),
+		aggregateWarningActive: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "aggregate_warning_active",
+			Help:      "跨网卡聚合流量警告是否已触发（1 表示已触发）",
+		}),
+		counterResets: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "traffic_monitor",
+			Name:      "counter_resets_total",
+			Help:      "检测到的网卡计数器回绕/重置次数（按网卡）",
+		}, []string{"interface"}),
+		notifierFailures: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "traffic_monitor",
+			Name:      "notifier_send_failures_total",
+			Help:      "通知发送失败次数（按通知渠道，重试耗尽后计数一次）",
+		}, []string{"notifier"}),
+	}
+
+	return m
+}
+
+// update 在每次 tick 后刷新单个网卡的计数器/仪表盘
+func (m *Metrics) update(interfaceName string, inDelta, outDelta, totalIn, totalOut uint64, warningActive bool) {
+	m.bytesInDelta.WithLabelValues(interfaceName).Set(float64(inDelta))
+	m.bytesOutDelta.WithLabelValues(interfaceName).Set(float64(outDelta))
+	m.bytesInTotal.WithLabelValues(interfaceName).Set(float64(totalIn))
+	m.bytesOutTotal.WithLabelValues(interfaceName).Set(float64(totalOut))
+	m.warningActive.WithLabelValues(interfaceName).Set(boolToFloat(warningActive))
+}
+
+// updateAggregate 刷新跨网卡聚合规则的告警状态
+func (m *Metrics) updateAggregate(active bool) {
+	m.aggregateWarningActive.Set(boolToFloat(active))
+}
+
+// updateCounterResets 刷新某个网卡累计探测到的计数器重置次数
+func (m *Metrics) updateCounterResets(interfaceName string, resets uint64) {
+	m.counterResets.WithLabelValues(interfaceName).Set(float64(resets))
+}
+
+// incrementNotifierFailure 记录一次通知渠道重试耗尽后的发送失败
+func (m *Metrics) incrementNotifierFailure(name string) {
+	m.notifierFailures.WithLabelValues(name).Inc()
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// startMetricsServer 启动内嵌的 HTTP 服务，暴露 /metrics 与 /history
+func (app *App) startMetricsServer() {
+	if app.Config.MetricsListen == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(app.Metrics.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/history", app.handleHistory)
+
+	server := &http.Server{
+		Addr:    app.Config.MetricsListen,
+		Handler: mux,
+	}
+	app.metricsServer = server
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		log.Printf("指标服务已启动，监听 %s", app.Config.MetricsListen)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("指标服务异常退出: %v", err)
+		}
+	}()
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		<-app.stopChan
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("关闭指标服务失败: %v", err)
+		}
+	}()
+}
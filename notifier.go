@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Event 是一次要对外发送的通知事件
+type Event struct {
+	Title   string
+	Message string
+	Time    time.Time
+}
+
+// Notifier 是一个通知渠道的统一接口，新增推送方式只需实现它
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+const (
+	notifierQueueSize   = 64
+	notifierMaxRetries  = 3
+	notifierSendTimeout = 10 * time.Second
+)
+
+// NotifierManager 把事件分发给所有配置的通知渠道，带退避重试，
+// 用带缓冲的队列把发送过程和监控主循环解耦
+type NotifierManager struct {
+	notifiers []Notifier
+	metrics   *Metrics
+	queue     chan Event
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewNotifierManager 创建通知管理器
+func NewNotifierManager(notifiers []Notifier, metrics *Metrics) *NotifierManager {
+	return &NotifierManager{
+		notifiers: notifiers,
+		metrics:   metrics,
+		queue:     make(chan Event, notifierQueueSize),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start 启动后台分发协程
+func (nm *NotifierManager) Start() {
+	nm.wg.Add(1)
+	go func() {
+		defer nm.wg.Done()
+		for {
+			select {
+			case event := <-nm.queue:
+				nm.dispatch(event)
+			case <-nm.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止分发协程
+func (nm *NotifierManager) Stop() {
+	close(nm.stopChan)
+	nm.wg.Wait()
+}
+
+// Notify 把事件放入发送队列；队列满时丢弃并记录日志，避免阻塞监控主循环
+func (nm *NotifierManager) Notify(event Event) {
+	select {
+	case nm.queue <- event:
+	default:
+		log.Printf("通知队列已满，丢弃事件: %s", event.Title)
+	}
+}
+
+// dispatch 并发地把一个事件发给所有通知渠道
+func (nm *NotifierManager) dispatch(event Event) {
+	var wg sync.WaitGroup
+	for _, n := range nm.notifiers {
+		wg.Add(1)
+		go func(n Notifier) {
+			defer wg.Done()
+			nm.sendWithRetry(n, event)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// sendWithRetry 对单个通知渠道做有限次指数退避重试
+func (nm *NotifierManager) sendWithRetry(n Notifier, event Event) {
+	backoff := time.Second
+
+	for attempt := 1; attempt <= notifierMaxRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), notifierSendTimeout)
+		err := n.Send(ctx, event)
+		cancel()
+
+		if err == nil {
+			return
+		}
+
+		log.Printf("通知发送失败 (%s，第 %d/%d 次): %v", n.Name(), attempt, notifierMaxRetries, err)
+
+		if attempt == notifierMaxRetries {
+			if nm.metrics != nil {
+				nm.metrics.incrementNotifierFailure(n.Name())
+			}
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// buildNotifiers 根据配置构造通知渠道列表；未配置 notifiers 时退化为
+// 直接使用 Telegram Bot，保持升级前的默认行为
+func buildNotifiers(config Config, bot *tgbotapi.BotAPI) ([]Notifier, error) {
+	if len(config.Notifiers) == 0 {
+		if bot == nil {
+			return nil, nil
+		}
+		return []Notifier{&telegramNotifier{bot: bot, chatIDs: config.TelegramChatIDs}}, nil
+	}
+
+	notifiers := make([]Notifier, 0, len(config.Notifiers))
+	for _, nc := range config.Notifiers {
+		notifier, err := buildNotifier(nc, bot, config.TelegramChatIDs)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
+
+func buildNotifier(nc NotifierConfig, bot *tgbotapi.BotAPI, telegramChatIDs []int64) (Notifier, error) {
+	switch nc.Type {
+	case "telegram":
+		return &telegramNotifier{bot: bot, chatIDs: telegramChatIDs}, nil
+	case "webhook":
+		if nc.URL == "" {
+			return nil, fmt.Errorf("webhook 通知缺少 url")
+		}
+		return &webhookNotifier{url: nc.URL, client: &http.Client{Timeout: notifierSendTimeout}}, nil
+	case "discord":
+		if nc.Webhook == "" {
+			return nil, fmt.Errorf("discord 通知缺少 webhook")
+		}
+		return &discordNotifier{webhookURL: nc.Webhook, client: &http.Client{Timeout: notifierSendTimeout}}, nil
+	case "smtp":
+		if nc.SMTPHost == "" || len(nc.To) == 0 {
+			return nil, fmt.Errorf("smtp 通知缺少 smtp_host 或 to")
+		}
+		return &smtpNotifier{config: nc}, nil
+	case "serverchan":
+		if nc.SendKey == "" {
+			return nil, fmt.Errorf("server酱通知缺少 send_key")
+		}
+		return &serverChanNotifier{sendKey: nc.SendKey, client: &http.Client{Timeout: notifierSendTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("未知的通知类型: %s", nc.Type)
+	}
+}
+
+// telegramNotifier 把事件发送给 TelegramChatIDs 中的每个会话
+type telegramNotifier struct {
+	bot     *tgbotapi.BotAPI
+	chatIDs []int64
+}
+
+func (t *telegramNotifier) Name() string { return "telegram" }
+
+func (t *telegramNotifier) Send(ctx context.Context, event Event) error {
+	if t.bot == nil {
+		return fmt.Errorf("telegram bot 未初始化")
+	}
+
+	var lastErr error
+	for _, chatID := range t.chatIDs {
+		msg := tgbotapi.NewMessage(chatID, event.Message)
+		if _, err := t.bot.Send(msg); err != nil {
+			lastErr = fmt.Errorf("chatID %d: %v", chatID, err)
+		}
+	}
+	return lastErr
+}
+
+// webhookNotifier 把事件以 JSON 形式 POST 给一个通用 webhook 地址
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (w *webhookNotifier) Name() string { return "webhook" }
+
+func (w *webhookNotifier) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"title":   event.Title,
+		"message": event.Message,
+		"time":    event.Time.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回非成功状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// discordNotifier 把事件发到 Discord 的 incoming webhook
+type discordNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func (d *discordNotifier) Name() string { return "discord" }
+
+func (d *discordNotifier) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"content": fmt.Sprintf("**%s**\n%s", event.Title, event.Message),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook 返回非成功状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// serverChanNotifier 通过 Server酱 (sctapi.ftqq.com) 推送到微信
+type serverChanNotifier struct {
+	sendKey string
+	client  *http.Client
+}
+
+func (s *serverChanNotifier) Name() string { return "serverchan" }
+
+func (s *serverChanNotifier) Send(ctx context.Context, event Event) error {
+	endpoint := fmt.Sprintf("https://sctapi.ftqq.com/%s.send", s.sendKey)
+
+	form := url.Values{}
+	form.Set("title", event.Title)
+	form.Set("desp", event.Message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server酱返回非成功状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// smtpNotifier 通过 SMTP 发送邮件
+type smtpNotifier struct {
+	config NotifierConfig
+}
+
+func (s *smtpNotifier) Name() string { return "smtp" }
+
+// Send 通过 SMTP 发送邮件；smtp.SendMail 本身没有超时机制，连接建立用
+// ctx 的 deadline 控制，连接建立后再把同一个 deadline 设到底层连接上，
+// 这样后续的整个 SMTP 会话（认证、MAIL/RCPT/DATA）都受 notifierSendTimeout 约束，
+// 不会因为对端无响应（防火墙丢包等）而无限期挂起
+func (s *smtpNotifier) Send(ctx context.Context, event Event) error {
+	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %v", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("设置 SMTP 连接超时失败: %v", err)
+		}
+	}
+
+	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+	if err != nil {
+		return fmt.Errorf("创建 SMTP 客户端失败: %v", err)
+	}
+	defer client.Close()
+
+	if s.config.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %v", err)
+		}
+	}
+
+	from := s.config.From
+	if from == "" {
+		from = s.config.SMTPUsername
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range s.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", event.Title)
+	fmt.Fprintf(&msg, "\r\n%s\r\n", event.Message)
+
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// startConfigWatcher 监听 SIGHUP 和 config.json 所在目录的文件修改事件，
+// 触发 Reload；fsnotify 初始化失败时热重载仍可通过 SIGHUP 使用
+func (app *App) startConfigWatcher() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	var events chan fsnotify.Event
+	var watcher *fsnotify.Watcher
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("创建配置文件监听器失败，热重载将仅响应 SIGHUP: %v", err)
+	} else if err := w.Add(filepath.Dir(app.configPath)); err != nil {
+		log.Printf("监听配置目录失败，热重载将仅响应 SIGHUP: %v", err)
+		w.Close()
+	} else {
+		watcher = w
+		events = w.Events
+	}
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		for {
+			select {
+			case <-sighup:
+				log.Println("收到 SIGHUP，重新加载配置")
+				app.Reload()
+			case event := <-events:
+				if filepath.Clean(event.Name) != filepath.Clean(app.configPath) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				log.Println("检测到配置文件变更，重新加载配置")
+				app.Reload()
+			case <-app.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Reload 重新读取配置文件，校验后原子地替换运行时安全的字段（阈值、检查间隔、
+// 会话列表、流量模式、通知渠道等），并通过 Telegram 播报变化摘要
+func (app *App) Reload() {
+	newConfig, err := loadConfig(app.configPath)
+	if err != nil {
+		log.Printf("重新加载配置失败: %v", err)
+		return
+	}
+
+	if err := validateConfig(newConfig); err != nil {
+		log.Printf("新配置校验失败，已忽略本次重载: %v", err)
+		return
+	}
+
+	newNotifiers, err := buildNotifiers(newConfig, app.Bot)
+	if err != nil {
+		log.Printf("重建通知渠道失败，已忽略本次重载: %v", err)
+		return
+	}
+
+	newManager := NewNotifierManager(newNotifiers, app.Metrics)
+	newManager.Start()
+
+	app.mu.Lock()
+	oldConfig := app.Config
+	intervalChanged := oldConfig.CheckIntervalSeconds != newConfig.CheckIntervalSeconds
+
+	app.Config.Interfaces = newConfig.Interfaces
+	app.Config.AggregateRule = newConfig.AggregateRule
+	app.Config.AggregateThresholdGB = newConfig.AggregateThresholdGB
+	app.Config.CheckIntervalSeconds = newConfig.CheckIntervalSeconds
+	app.Config.TelegramChatIDs = newConfig.TelegramChatIDs
+	app.Config.ShutdownOnWarning = newConfig.ShutdownOnWarning
+	app.Config.ShutdownPolicy = newConfig.ShutdownPolicy
+	app.Config.Notifiers = newConfig.Notifiers
+
+	oldNotifiers := app.Notifiers
+	app.Notifiers = newManager
+	app.notify("配置已热重载", summarizeConfigChanges(oldConfig, newConfig))
+	app.mu.Unlock()
+
+	if oldNotifiers != nil {
+		oldNotifiers.Stop()
+	}
+
+	if intervalChanged {
+		select {
+		case app.reloadTickerChan <- newConfig.CheckIntervalSeconds:
+		default:
+		}
+	}
+
+	log.Println("配置热重载完成")
+}
+
+// validateConfig 校验配置的基本合法性；在启动时调用可以避免网卡配置丢失或写坏后
+// 静默监控不到任何网卡，在热重载时调用可以避免一次写坏的 config.json 被直接加载
+func validateConfig(config Config) error {
+	if len(config.Interfaces) == 0 {
+		return fmt.Errorf("配置中至少需要一个网络接口")
+	}
+	if config.CheckIntervalSeconds <= 0 {
+		return fmt.Errorf("check_interval_seconds 必须大于 0")
+	}
+	for _, cfg := range config.Interfaces {
+		switch cfg.TrafficMode {
+		case "in", "out", "max", "both":
+		default:
+			return fmt.Errorf("接口 %s 的 traffic_mode 无效: %s", cfg.Name, cfg.TrafficMode)
+		}
+	}
+	switch config.ShutdownPolicy.Method {
+	case "", "poweroff", "halt", "reboot", "systemctl":
+	default:
+		return fmt.Errorf("shutdown_policy.method 无效: %s", config.ShutdownPolicy.Method)
+	}
+	return nil
+}
+
+// summarizeConfigChanges 生成一份供 Telegram 播报的配置变更摘要
+func summarizeConfigChanges(old, new Config) string {
+	var changes []string
+
+	if old.CheckIntervalSeconds != new.CheckIntervalSeconds {
+		changes = append(changes, fmt.Sprintf("检查间隔: %d -> %d 秒", old.CheckIntervalSeconds, new.CheckIntervalSeconds))
+	}
+	if !equalInt64Slices(old.TelegramChatIDs, new.TelegramChatIDs) {
+		changes = append(changes, "Telegram 会话列表已更新")
+	}
+	if old.AggregateRule != new.AggregateRule || old.AggregateThresholdGB != new.AggregateThresholdGB {
+		changes = append(changes, fmt.Sprintf("聚合规则: %s(%.2fGB) -> %s(%.2fGB)",
+			old.AggregateRule, old.AggregateThresholdGB, new.AggregateRule, new.AggregateThresholdGB))
+	}
+	if len(old.Interfaces) != len(new.Interfaces) {
+		changes = append(changes, fmt.Sprintf("接口数量: %d -> %d", len(old.Interfaces), len(new.Interfaces)))
+	} else {
+		for i := range old.Interfaces {
+			if old.Interfaces[i] != new.Interfaces[i] {
+				changes = append(changes, fmt.Sprintf("接口 %s 配置已更新", new.Interfaces[i].DisplayLabel()))
+			}
+		}
+	}
+	if len(old.Notifiers) != len(new.Notifiers) {
+		changes = append(changes, fmt.Sprintf("通知渠道数量: %d -> %d", len(old.Notifiers), len(new.Notifiers)))
+	}
+
+	if len(changes) == 0 {
+		return "配置已重新加载，没有检测到变化"
+	}
+	return "配置已重新加载:\n- " + strings.Join(changes, "\n- ")
+}
+
+func equalInt64Slices(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
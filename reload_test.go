@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func validConfig() Config {
+	return Config{
+		Interfaces: []InterfaceConfig{
+			{Name: "eth0", TrafficMode: "both", ThresholdGB: 100},
+		},
+		CheckIntervalSeconds: 60,
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := validateConfig(validConfig()); err != nil {
+		t.Errorf("validateConfig(valid) = %v, want nil", err)
+	}
+
+	noInterfaces := validConfig()
+	noInterfaces.Interfaces = nil
+	if err := validateConfig(noInterfaces); err == nil {
+		t.Error("validateConfig(no interfaces) = nil, want error")
+	}
+
+	badInterval := validConfig()
+	badInterval.CheckIntervalSeconds = 0
+	if err := validateConfig(badInterval); err == nil {
+		t.Error("validateConfig(check_interval_seconds=0) = nil, want error")
+	}
+
+	badMode := validConfig()
+	badMode.Interfaces[0].TrafficMode = "sideways"
+	if err := validateConfig(badMode); err == nil {
+		t.Error("validateConfig(invalid traffic_mode) = nil, want error")
+	}
+
+	for _, method := range []string{"", "poweroff", "halt", "reboot", "systemctl"} {
+		cfg := validConfig()
+		cfg.ShutdownPolicy.Method = method
+		if err := validateConfig(cfg); err != nil {
+			t.Errorf("validateConfig(method=%q) = %v, want nil", method, err)
+		}
+	}
+
+	badMethod := validConfig()
+	badMethod.ShutdownPolicy.Method = "shutdown -h now"
+	if err := validateConfig(badMethod); err == nil {
+		t.Error("validateConfig(invalid shutdown_policy.method) = nil, want error")
+	}
+}
+
+func TestSummarizeConfigChangesNoChange(t *testing.T) {
+	cfg := validConfig()
+	if got := summarizeConfigChanges(cfg, cfg); got != "配置已重新加载，没有检测到变化" {
+		t.Errorf("summarizeConfigChanges(same, same) = %q, want 无变化提示", got)
+	}
+}
+
+func TestSummarizeConfigChangesDetectsIntervalChange(t *testing.T) {
+	old := validConfig()
+	new := validConfig()
+	new.CheckIntervalSeconds = 120
+
+	got := summarizeConfigChanges(old, new)
+	if got == "配置已重新加载，没有检测到变化" {
+		t.Error("summarizeConfigChanges 未检测到 check_interval_seconds 变化")
+	}
+}
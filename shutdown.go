@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ShutdownPolicy 描述达到关机条件后应该如何执行关机
+type ShutdownPolicy struct {
+	GracePeriodSeconds int    `json:"grace_period_seconds"` // 执行关机前的宽限期（秒），默认 10
+	RequireConfirm     bool   `json:"require_confirm"`      // 是否要求通过 /shutdown_confirm 确认后才真正关机
+	DryRun             bool   `json:"dry_run"`              // 为 true 时只记录日志，不执行真正的关机，用于演练流程
+	HookCommand        string `json:"hook_command"`         // 关机前执行的自定义命令，可为空
+	Method             string `json:"method"`               // 关机方式："poweroff"（默认）、"halt"、"reboot" 或 "systemctl"
+}
+
+// gracePeriod 返回宽限期时长，未配置时回退到 10 秒
+func (p ShutdownPolicy) gracePeriod() time.Duration {
+	if p.GracePeriodSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(p.GracePeriodSeconds) * time.Second
+}
+
+// pendingShutdown 记录一次正在宽限期内等待执行（或等待确认）的关机
+type pendingShutdown struct {
+	token       string
+	cancel      chan struct{}
+	confirmed   chan struct{}
+	confirmOnce sync.Once // 保证 confirmed 只被 close 一次，避免重复 /shutdown_confirm 导致 panic
+}
+
+// scheduleShutdown 按照 ShutdownPolicy 安排一次关机：先等待宽限期，如果策略要求
+// 确认，则必须在宽限期内收到 /shutdown_confirm <token> 才会真正执行，否则超时自动取消；
+// 调用方需持有 app.mu（目前唯一的调用方 sendWarningMessage 已在锁内），已有关机在
+// 宽限期内等待时直接忽略，避免同一轮 tick 里多个网卡越阈值各自排出一次关机，
+// 导致 /shutdown_cancel 只取消得了最后一个
+func (app *App) scheduleShutdown() {
+	if app.pendingShutdown != nil {
+		log.Println("已有关机在宽限期内等待执行/确认，忽略本次新的关机请求")
+		return
+	}
+
+	policy := app.Config.ShutdownPolicy
+
+	token, err := generateShutdownToken()
+	if err != nil {
+		log.Printf("生成关机确认口令失败: %v", err)
+	}
+
+	pending := &pendingShutdown{
+		token:     token,
+		cancel:    make(chan struct{}),
+		confirmed: make(chan struct{}),
+	}
+
+	app.pendingShutdown = pending
+	app.shutdownCancelChan = pending.cancel
+
+	if policy.RequireConfirm {
+		app.notify("关机待确认", fmt.Sprintf("已达到关机条件，请在 %s 内发送 /shutdown_confirm %s 确认执行，超时或 /shutdown_cancel 将取消本次关机", policy.gracePeriod(), token))
+	} else {
+		app.notify("即将关机", fmt.Sprintf("已达到关机条件，将在 %s 后执行关机，可通过 /shutdown_cancel 取消", policy.gracePeriod()))
+	}
+
+	go func() {
+		defer app.clearPendingShutdown(pending)
+
+		select {
+		case <-pending.confirmed:
+			app.executeShutdown(policy)
+		case <-time.After(policy.gracePeriod()):
+			if policy.RequireConfirm {
+				log.Println("关机确认超时，已自动取消")
+				return
+			}
+			app.executeShutdown(policy)
+		case <-pending.cancel:
+			log.Println("关机已通过 /shutdown_cancel 取消")
+		}
+	}()
+}
+
+// confirmShutdown 校验确认口令并唤醒等待中的关机协程；token 不匹配或没有待执行的关机时返回 false。
+// 两次并发的 /shutdown_confirm（多个管理员同时确认，或网络重发）可能都带着同一个合法 token
+// 走到这里，confirmOnce 保证 confirmed 只被 close 一次，第二次调用会被忽略而不是 panic
+func (app *App) confirmShutdown(token string) bool {
+	app.mu.Lock()
+	pending := app.pendingShutdown
+	app.mu.Unlock()
+
+	if pending == nil || pending.token == "" || pending.token != token {
+		return false
+	}
+
+	pending.confirmOnce.Do(func() {
+		close(pending.confirmed)
+	})
+	return true
+}
+
+// clearPendingShutdown 清理已经结束（执行/取消/超时）的关机状态，避免和后续新的关机互相干扰
+func (app *App) clearPendingShutdown(pending *pendingShutdown) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.pendingShutdown == pending {
+		app.pendingShutdown = nil
+		app.shutdownCancelChan = nil
+	}
+}
+
+// executeShutdown 执行关机前置命令（如有）并按策略真正关机；dry_run 模式下只记录日志
+func (app *App) executeShutdown(policy ShutdownPolicy) {
+	if policy.HookCommand != "" {
+		log.Printf("执行关机前置命令: %s", policy.HookCommand)
+		if err := exec.Command("sh", "-c", policy.HookCommand).Run(); err != nil {
+			log.Printf("关机前置命令执行失败: %v", err)
+		}
+	}
+
+	if policy.DryRun {
+		log.Println("dry_run 已启用，跳过实际关机操作")
+		return
+	}
+
+	log.Println("正在执行关机...")
+	if err := performShutdown(policy.Method); err != nil {
+		log.Printf("执行关机失败: %v", err)
+	}
+}
+
+// generateShutdownToken 生成一个随机的关机确认口令
+func generateShutdownToken() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
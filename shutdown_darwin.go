@@ -0,0 +1,21 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// performShutdown 在 macOS 上没有 Linux 风格的 reboot 系统调用，改为调用系统自带的
+// shutdown 命令；macOS 没有 systemctl 概念，视为非法方式
+func performShutdown(method string) error {
+	switch method {
+	case "reboot":
+		return exec.Command("shutdown", "-r", "now").Run()
+	case "halt", "poweroff", "":
+		return exec.Command("shutdown", "-h", "now").Run()
+	default:
+		return fmt.Errorf("未知的关机方式: %s", method)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// performShutdown 在 Linux 上根据 method 执行关机/重启；"systemctl" 委托给
+// systemd 以保证与其电源管理状态一致，其余方式直接走 syscall.Reboot
+func performShutdown(method string) error {
+	switch method {
+	case "reboot":
+		syscall.Sync()
+		return syscall.Reboot(syscall.LINUX_REBOOT_CMD_RESTART)
+	case "halt":
+		syscall.Sync()
+		return syscall.Reboot(syscall.LINUX_REBOOT_CMD_HALT)
+	case "systemctl":
+		return exec.Command("systemctl", "poweroff").Run()
+	case "poweroff", "":
+		syscall.Sync()
+		return syscall.Reboot(syscall.LINUX_REBOOT_CMD_POWER_OFF)
+	default:
+		return fmt.Errorf("未知的关机方式: %s", method)
+	}
+}
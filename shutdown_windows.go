@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// performShutdown 在 Windows 上通过 shutdown.exe 执行关机/重启；Windows 没有
+// Linux 风格的 halt/systemctl 概念，"halt" 退化为普通关机，"systemctl" 视为非法方式
+func performShutdown(method string) error {
+	switch method {
+	case "reboot":
+		return exec.Command("shutdown", "/r", "/t", "0").Run()
+	case "halt", "poweroff", "":
+		return exec.Command("shutdown", "/s", "/t", "0").Run()
+	default:
+		return fmt.Errorf("未知的关机方式: %s", method)
+	}
+}
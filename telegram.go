@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CommandHandler 处理一条 Telegram 命令并返回要回复的文本
+type CommandHandler func(message *tgbotapi.Message) string
+
+// RegisterCommand 注册一个命令处理函数，供未来扩展新命令使用
+func (app *App) RegisterCommand(name string, handler CommandHandler) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.commands[name] = handler
+}
+
+// registerBuiltinCommands 注册内置命令
+func (app *App) registerBuiltinCommands() {
+	app.RegisterCommand("status", app.cmdStatus)
+	app.RegisterCommand("report", app.cmdReport)
+	app.RegisterCommand("reset", app.cmdReset)
+	app.RegisterCommand("setthreshold", app.cmdSetThreshold)
+	app.RegisterCommand("mode", app.cmdMode)
+	app.RegisterCommand("mute", app.cmdMute)
+	app.RegisterCommand("shutdown_cancel", app.cmdShutdownCancel)
+	app.RegisterCommand("shutdown_confirm", app.cmdShutdownConfirm)
+}
+
+// startTelegramListener 以长轮询方式接收 Telegram 更新并分发命令
+func (app *App) startTelegramListener() {
+	if app.Bot == nil {
+		return
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := app.Bot.GetUpdatesChan(u)
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		defer app.Bot.StopReceivingUpdates()
+
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					return
+				}
+				app.handleUpdate(update)
+			case <-app.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// handleUpdate 处理单条更新，做 ACL 校验并分发到命令处理函数
+func (app *App) handleUpdate(update tgbotapi.Update) {
+	if update.Message == nil || !update.Message.IsCommand() {
+		return
+	}
+
+	message := update.Message
+	if !app.isChatAllowed(message.Chat.ID) {
+		log.Printf("拒绝来自未授权会话的命令 (chatID: %d): /%s", message.Chat.ID, message.Command())
+		return
+	}
+
+	app.mu.Lock()
+	handler, ok := app.commands[message.Command()]
+	app.mu.Unlock()
+
+	var reply string
+	if !ok {
+		reply = fmt.Sprintf("未知命令: /%s", message.Command())
+	} else {
+		reply = handler(message)
+	}
+
+	if reply == "" {
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, reply)
+	if _, err := app.Bot.Send(msg); err != nil {
+		log.Printf("回复命令失败 (chatID: %d): %v", message.Chat.ID, err)
+	}
+}
+
+// isChatAllowed 检查会话是否在 TelegramChatIDs 白名单内；白名单为空时放行所有会话
+func (app *App) isChatAllowed(chatID int64) bool {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if len(app.Config.TelegramChatIDs) == 0 {
+		return true
+	}
+	for _, id := range app.Config.TelegramChatIDs {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdStatus 返回当前流量统计概况
+func (app *App) cmdStatus(message *tgbotapi.Message) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	muted := "否"
+	if app.Muted {
+		muted = "是"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "📈 [%s] 当前状态", app.Config.ServerName)
+
+	for _, cfg := range app.Config.Interfaces {
+		stats := app.Stats.Interfaces[cfg.Name]
+		if stats == nil {
+			continue
+		}
+		fmt.Fprintf(&body, "\n\n%s (%s):\n- 入站流量: %.2f GB\n- 出站流量: %.2f GB\n- 警告阈值: %.2f GB\n- 本月已告警: %v\n- 计数器重置次数: %d",
+			cfg.DisplayLabel(), getTrafficModeDescription(cfg.TrafficMode),
+			bytesToGB(stats.BytesIn), bytesToGB(stats.BytesOut),
+			cfg.ThresholdGB, stats.WarningsSentThisMonth, stats.CounterResets)
+	}
+
+	if app.Config.AggregateRule != "" {
+		fmt.Fprintf(&body, "\n\n聚合(%s): %.2f GB / %.2f GB，本月已告警: %v",
+			app.Config.AggregateRule, bytesToGB(app.aggregateTraffic()), app.Config.AggregateThresholdGB, app.Stats.AggregateWarningSent)
+	}
+
+	fmt.Fprintf(&body, "\n\n静音中: %s\n下次重置: %s", muted, app.Stats.NextResetTime.Format("2006-01-02"))
+
+	return body.String()
+}
+
+// cmdReport 立即生成一份月度报告
+func (app *App) cmdReport(message *tgbotapi.Message) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.sendMonthlyReport()
+	return "已生成月度报告"
+}
+
+// cmdReset 手动重置本月流量统计
+func (app *App) cmdReset(message *tgbotapi.Message) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.resetMonthlyStats()
+	if err := app.saveStats(); err != nil {
+		return fmt.Sprintf("重置失败: %v", err)
+	}
+	return "已重置本月流量统计"
+}
+
+// cmdSetThreshold 修改某个网卡（或 all）的警告阈值，参数为 "<接口> <GB>"
+func (app *App) cmdSetThreshold(message *tgbotapi.Message) string {
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) != 2 {
+		return "用法: /setthreshold <接口名|all> <GB>，例如 /setthreshold eth0 500"
+	}
+
+	threshold, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || threshold <= 0 {
+		return "用法: /setthreshold <接口名|all> <GB>，例如 /setthreshold eth0 500"
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if fields[0] == "all" {
+		for i := range app.Config.Interfaces {
+			app.Config.Interfaces[i].ThresholdGB = threshold
+		}
+		return fmt.Sprintf("所有接口的警告阈值已设置为 %.2f GB", threshold)
+	}
+
+	cfg := app.findInterfaceConfig(fields[0])
+	if cfg == nil {
+		return fmt.Sprintf("未找到接口: %s", fields[0])
+	}
+	cfg.ThresholdGB = threshold
+	return fmt.Sprintf("%s 的警告阈值已设置为 %.2f GB", cfg.DisplayLabel(), threshold)
+}
+
+// cmdMode 修改某个网卡（或 all）的流量统计模式，参数为 "<接口> <in|out|max|both>"
+func (app *App) cmdMode(message *tgbotapi.Message) string {
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) != 2 {
+		return "用法: /mode <接口名|all> <in|out|max|both>"
+	}
+
+	mode := strings.ToLower(fields[1])
+	switch mode {
+	case "in", "out", "max", "both":
+	default:
+		return "用法: /mode <接口名|all> <in|out|max|both>"
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if fields[0] == "all" {
+		for i := range app.Config.Interfaces {
+			app.Config.Interfaces[i].TrafficMode = mode
+		}
+		return fmt.Sprintf("所有接口的流量模式已切换为 %s", getTrafficModeDescription(mode))
+	}
+
+	cfg := app.findInterfaceConfig(fields[0])
+	if cfg == nil {
+		return fmt.Sprintf("未找到接口: %s", fields[0])
+	}
+	cfg.TrafficMode = mode
+	return fmt.Sprintf("%s 的流量模式已切换为 %s", cfg.DisplayLabel(), getTrafficModeDescription(mode))
+}
+
+// cmdMute 切换静音状态，静音期间不再发送流量警告
+func (app *App) cmdMute(message *tgbotapi.Message) string {
+	app.mu.Lock()
+	app.Muted = !app.Muted
+	muted := app.Muted
+	app.mu.Unlock()
+
+	if muted {
+		return "已静音，本月将不再发送流量警告"
+	}
+	return "已取消静音"
+}
+
+// cmdShutdownCancel 取消正在等待执行的关机
+func (app *App) cmdShutdownCancel(message *tgbotapi.Message) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.shutdownCancelChan == nil {
+		return "当前没有等待执行的关机"
+	}
+
+	close(app.shutdownCancelChan)
+	app.shutdownCancelChan = nil
+	return "已取消本次关机"
+}
+
+// cmdShutdownConfirm 用等待中的口令确认一次关机，参数为 "<口令>"
+func (app *App) cmdShutdownConfirm(message *tgbotapi.Message) string {
+	token := strings.TrimSpace(message.CommandArguments())
+	if token == "" {
+		return "用法: /shutdown_confirm <口令>"
+	}
+
+	if !app.confirmShutdown(token) {
+		return "口令不正确，或当前没有等待确认的关机"
+	}
+	return "已确认，关机将立即执行"
+}
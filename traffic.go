@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// InterfaceConfig 描述一个被监控的网络接口
+type InterfaceConfig struct {
+	Name        string  `json:"name"`            // 网卡名称，如 eth0
+	Label       string  `json:"label,omitempty"` // 展示用的别名，留空则使用 Name
+	TrafficMode string  `json:"traffic_mode"`    // 流量模式：in, out, max, both
+	ThresholdGB float64 `json:"threshold_gb"`    // 告警阈值（GB），0 表示不单独告警
+}
+
+// DisplayLabel 返回用于展示的接口名称，未设置 Label 时回退到 Name
+func (c InterfaceConfig) DisplayLabel() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.Name
+}
+
+// InterfaceStats 是单个网络接口的累计流量
+type InterfaceStats struct {
+	BytesIn               uint64 `json:"bytes_in"`
+	BytesOut              uint64 `json:"bytes_out"`
+	LastBytesIn           uint64 `json:"last_bytes_in"`
+	LastBytesOut          uint64 `json:"last_bytes_out"`
+	WarningsSentThisMonth bool   `json:"warnings_sent_this_month"`
+	CounterResets         uint64 `json:"counter_resets"` // 检测到的计数器回绕/重置次数
+}
+
+// 流量统计结构体，按网卡名称索引
+type TrafficStats struct {
+	CurrentMonth         string                     `json:"current_month"`
+	LastResetTime        time.Time                  `json:"last_reset_time"`
+	NextResetTime        time.Time                  `json:"next_reset_time"`
+	Interfaces           map[string]*InterfaceStats `json:"interfaces"`
+	AggregateWarningSent bool                       `json:"aggregate_warning_sent"`
+	BootID               string                     `json:"boot_id"` // 上一次检查时的主机开机标识，用于探测重启
+}
+
+// legacyTrafficStats 是重构前的单网卡统计格式，仅用于首次启动时的迁移
+type legacyTrafficStats struct {
+	CurrentMonth          string    `json:"current_month"`
+	LastResetTime         time.Time `json:"last_reset_time"`
+	NextResetTime         time.Time `json:"next_reset_time"`
+	BytesIn               uint64    `json:"bytes_in"`
+	BytesOut              uint64    `json:"bytes_out"`
+	LastBytesIn           uint64    `json:"last_bytes_in"`
+	LastBytesOut          uint64    `json:"last_bytes_out"`
+	WarningsSentThisMonth bool      `json:"warnings_sent_this_month"`
+}
+
+// isLegacyStatsFormat 判断磁盘上的统计文件是否是重构前的单网卡格式
+func isLegacyStatsFormat(data []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	_, hasInterfaces := probe["interfaces"]
+	_, hasLegacyBytesIn := probe["bytes_in"]
+	return !hasInterfaces && hasLegacyBytesIn
+}
+
+// migrateLegacyStats 把单网卡的 traffic_stats.json 迁移到按接口分组的新格式，
+// 月度到量累计挂到配置中的第一个接口上，避免用户升级后丢失本月数据
+func (app *App) migrateLegacyStats(data []byte) error {
+	var legacy legacyTrafficStats
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("解析旧版统计数据失败: %v", err)
+	}
+
+	if len(app.Config.Interfaces) == 0 {
+		return fmt.Errorf("无法迁移旧版统计数据: 配置中没有任何网络接口")
+	}
+
+	primary := app.Config.Interfaces[0].Name
+	app.Stats = TrafficStats{
+		CurrentMonth:  legacy.CurrentMonth,
+		LastResetTime: legacy.LastResetTime,
+		NextResetTime: legacy.NextResetTime,
+		Interfaces: map[string]*InterfaceStats{
+			primary: {
+				BytesIn:               legacy.BytesIn,
+				BytesOut:              legacy.BytesOut,
+				LastBytesIn:           legacy.LastBytesIn,
+				LastBytesOut:          legacy.LastBytesOut,
+				WarningsSentThisMonth: legacy.WarningsSentThisMonth,
+			},
+		},
+	}
+
+	log.Printf("已将旧版单网卡统计数据迁移到接口 %s，本月到量不会丢失", primary)
+	return app.saveStats()
+}
+
+// resetMonthlyStats 将当月累计流量清零并计算下一次重置时间，不改变计数器基准值（LastBytesIn/Out）
+func (app *App) resetMonthlyStats() {
+	now := time.Now()
+	app.Stats.CurrentMonth = now.Format("2006-01")
+	app.Stats.LastResetTime = now
+	app.Stats.NextResetTime = getNextResetTime(now, app.Config.MonthlyResetDay)
+	app.Stats.AggregateWarningSent = false
+
+	for _, stats := range app.Stats.Interfaces {
+		stats.BytesIn = 0
+		stats.BytesOut = 0
+		stats.WarningsSentThisMonth = false
+	}
+}
+
+// findInterfaceConfig 返回指向配置中同名网卡条目的指针，便于运行时修改
+func (app *App) findInterfaceConfig(name string) *InterfaceConfig {
+	for i := range app.Config.Interfaces {
+		if app.Config.Interfaces[i].Name == name {
+			return &app.Config.Interfaces[i]
+		}
+	}
+	return nil
+}
+
+// getCurrentTrafficBytes 用一次 net.IOCounters(true) 调用取回所有网卡的计数器
+func getCurrentTrafficBytes() ([]net.IOCountersStat, error) {
+	return net.IOCounters(true)
+}
+
+// computeDelta 计算计数器增量；如果本次读数小于上次读数（网卡计数器回绕、
+// 接口被替换或重置），说明不能直接相减，而应把本次读数整体计入总量
+func computeDelta(current, last uint64) (delta uint64, reset bool) {
+	if current < last {
+		return current, true
+	}
+	return current - last, false
+}
+
+// findCounter 在一次性取回的计数器切片中查找指定网卡
+func findCounter(counters []net.IOCountersStat, name string) (net.IOCountersStat, bool) {
+	for _, counter := range counters {
+		if counter.Name == name {
+			return counter, true
+		}
+	}
+	return net.IOCountersStat{}, false
+}
+
+// trafficTotal 按流量模式把入站/出站字节数归约成一个总量
+func trafficTotal(mode string, bytesIn, bytesOut uint64) uint64 {
+	switch mode {
+	case "in":
+		return bytesIn
+	case "out":
+		return bytesOut
+	case "max":
+		if bytesIn > bytesOut {
+			return bytesIn
+		}
+		return bytesOut
+	case "both":
+		return bytesIn + bytesOut
+	default:
+		return bytesIn + bytesOut
+	}
+}
+
+// gbToBytes 把 GB 转换成字节数，用于和计数器比较
+func gbToBytes(gb float64) uint64 {
+	return uint64(gb * 1024 * 1024 * 1024)
+}
+
+// bytesToGB 把字节数转换成 GB，用于展示
+func bytesToGB(bytes uint64) float64 {
+	return float64(bytes) / 1024 / 1024 / 1024
+}
+
+// aggregateTraffic 按配置的聚合规则（sum/max）汇总所有网卡的流量
+func (app *App) aggregateTraffic() uint64 {
+	var sum, max uint64
+
+	for name, stats := range app.Stats.Interfaces {
+		mode := "both"
+		if cfg := app.findInterfaceConfig(name); cfg != nil {
+			mode = cfg.TrafficMode
+		}
+
+		total := trafficTotal(mode, stats.BytesIn, stats.BytesOut)
+		sum += total
+		if total > max {
+			max = total
+		}
+	}
+
+	if app.Config.AggregateRule == "max" {
+		return max
+	}
+	return sum
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestComputeDelta(t *testing.T) {
+	cases := []struct {
+		name          string
+		current, last uint64
+		wantDelta     uint64
+		wantReset     bool
+	}{
+		{"normal increase", 150, 100, 50, false},
+		{"no change", 100, 100, 0, false},
+		{"counter wrapped or reset", 10, 100, 10, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			delta, reset := computeDelta(c.current, c.last)
+			if delta != c.wantDelta || reset != c.wantReset {
+				t.Errorf("computeDelta(%d, %d) = (%d, %v), want (%d, %v)",
+					c.current, c.last, delta, reset, c.wantDelta, c.wantReset)
+			}
+		})
+	}
+}
+
+func TestTrafficTotal(t *testing.T) {
+	cases := []struct {
+		mode                    string
+		bytesIn, bytesOut, want uint64
+	}{
+		{"in", 10, 20, 10},
+		{"out", 10, 20, 20},
+		{"max", 10, 20, 20},
+		{"max", 30, 20, 30},
+		{"both", 10, 20, 30},
+		{"unknown", 10, 20, 30},
+	}
+
+	for _, c := range cases {
+		got := trafficTotal(c.mode, c.bytesIn, c.bytesOut)
+		if got != c.want {
+			t.Errorf("trafficTotal(%q, %d, %d) = %d, want %d", c.mode, c.bytesIn, c.bytesOut, got, c.want)
+		}
+	}
+}
+
+func TestGBBytesConversion(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	if got := gbToBytes(1); got != gib {
+		t.Errorf("gbToBytes(1) = %d, want %d", got, uint64(gib))
+	}
+	if got := bytesToGB(uint64(2 * gib)); got != 2 {
+		t.Errorf("bytesToGB(2GiB) = %v, want 2", got)
+	}
+}